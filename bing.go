@@ -0,0 +1,130 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// BingProvider searches Bing Visual Search by driving its reverse image
+// search upload flow.
+type BingProvider struct{}
+
+func (BingProvider) Name() string { return "bing" }
+
+func (b BingProvider) SearchByFile(ctx context.Context, path string) ([]ImageData, error) {
+	var file, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileContents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf = new(bytes.Buffer)
+	var writer = multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("cbir", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(fileContents); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.bing.com/images/search?view=detailv2&iss=sbiupload", buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.parseResults(contents)
+}
+
+func (b BingProvider) SearchByURL(ctx context.Context, imageURL string) ([]ImageData, error) {
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://www.bing.com/images/search?view=detailv2&iss=sbi&q=imgurl:"+url.QueryEscape(imageURL), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.parseResults(contents)
+}
+
+// parseResults scrapes the "murl"/"width"/"height" JSON fields Bing embeds
+// in its results page for each candidate image's URL and dimensions.
+func (b BingProvider) parseResults(contents []byte) ([]ImageData, error) {
+	if bytes.Contains(contents, []byte("captcha")) {
+		return nil, ErrCaptcha
+	}
+
+	var r, err = regexp.Compile(`"murl":"(.*?)".*?"width":(\d+),"height":(\d+)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []ImageData
+	for _, i := range r.FindAllStringSubmatch(string(contents), -1) {
+		if len(i) < 4 {
+			continue
+		}
+
+		imgURL, err := url.Parse(i[1])
+		if err != nil {
+			continue
+		}
+
+		width, err := strconv.Atoi(i[2])
+		if err != nil {
+			continue
+		}
+
+		height, err := strconv.Atoi(i[3])
+		if err != nil {
+			continue
+		}
+
+		data = append(data, ImageData{
+			URL:  imgURL.String(),
+			Area: width * height,
+		})
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoResults
+	}
+
+	return data, nil
+}
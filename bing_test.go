@@ -0,0 +1,38 @@
+package imageupsizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBingProviderParseResults(t *testing.T) {
+	t.Parallel()
+
+	var page = `var data = [{"murl":"https://example.com/cat-large.jpg","t":"Cat","width":1600,"height":900},` +
+		`{"murl":"https://example.com/cat-small.jpg","width":320,"height":180}];`
+
+	var data, err = BingProvider{}.parseResults([]byte(page))
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	assert.Equal(t, "https://example.com/cat-large.jpg", data[0].URL)
+	assert.Equal(t, 1600*900, data[0].Area)
+	assert.Equal(t, "https://example.com/cat-small.jpg", data[1].URL)
+	assert.Equal(t, 320*180, data[1].Area)
+}
+
+func TestBingProviderParseResultsCaptcha(t *testing.T) {
+	t.Parallel()
+
+	var _, err = BingProvider{}.parseResults([]byte(`<div>please solve this captcha</div>`))
+	assert.ErrorIs(t, err, ErrCaptcha)
+}
+
+func TestBingProviderParseResultsNoMatches(t *testing.T) {
+	t.Parallel()
+
+	var _, err = BingProvider{}.parseResults([]byte(`<html><body>no images here</body></html>`))
+	assert.ErrorIs(t, err, ErrNoResults)
+}
@@ -0,0 +1,22 @@
+package imageupsizer
+
+// Cache stores resolved search results and downloaded image bytes so
+// repeated runs over the same input skip the network entirely. Resolved
+// entries are keyed on the SHA-256 hex digest of the input file's bytes for
+// GetBiggerImageFromFileCached, or the request URL for GetBiggerImageCached;
+// image bytes are keyed on the downloaded image's own URL.
+type Cache interface {
+	// GetResolved returns the previously resolved winning ImageData for
+	// key (its URL and metadata, not its bytes), if present and unexpired.
+	GetResolved(key string) (*ImageData, bool)
+
+	// PutResolved caches data's URL and metadata under key.
+	PutResolved(key string, data *ImageData) error
+
+	// GetBytes returns the cached image bytes for key, if present and
+	// unexpired.
+	GetBytes(key string) ([]byte, bool)
+
+	// PutBytes caches image bytes under key.
+	PutBytes(key string, data []byte) error
+}
@@ -0,0 +1,203 @@
+package imageupsizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileCache is a filesystem-backed Cache. Resolved metadata is stored as
+// "<dir>/<hash>.json" and image bytes as "<dir>/<hash>.bin", where hash is
+// the SHA-256 hex digest of the caller's key. Entries whose mtime is older
+// than TTL are treated as misses; since every hit bumps mtime, TTL counts
+// from an entry's last write or hit, so an entry that keeps being read does
+// not expire. Once the directory exceeds MaxBytes the least
+// recently used entries (by mtime, which read bumps on every hit) are
+// evicted to make room. Writes are atomic (temp file + rename) and reads
+// and writes share a lock, so concurrent callers never observe a partial
+// write or a file evict() is mid-way through removing.
+type FileCache struct {
+	Dir      string
+	TTL      time.Duration
+	MaxBytes int64
+
+	mu sync.RWMutex
+}
+
+// NewFileCache creates (if necessary) dir and returns a FileCache rooted
+// there. A zero TTL means entries never expire, and a zero MaxBytes means
+// no eviction is performed.
+func NewFileCache(dir string, ttl time.Duration, maxBytes int64) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileCache{Dir: dir, TTL: ttl, MaxBytes: maxBytes}, nil
+}
+
+func (f *FileCache) GetResolved(key string) (*ImageData, bool) {
+	body, ok := f.read(f.metaPath(key))
+	if !ok {
+		return nil, false
+	}
+
+	var data ImageData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, false
+	}
+
+	return &data, true
+}
+
+func (f *FileCache) PutResolved(key string, data *ImageData) error {
+	var stored = *data
+	stored.Bytes = nil
+
+	body, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+
+	return f.write(f.metaPath(key), body)
+}
+
+func (f *FileCache) GetBytes(key string) ([]byte, bool) {
+	return f.read(f.binPath(key))
+}
+
+func (f *FileCache) PutBytes(key string, data []byte) error {
+	return f.write(f.binPath(key), data)
+}
+
+func (f *FileCache) metaPath(key string) string {
+	return filepath.Join(f.Dir, hashKey(key)+".json")
+}
+
+func (f *FileCache) binPath(key string) string {
+	return filepath.Join(f.Dir, hashKey(key)+".bin")
+}
+
+func (f *FileCache) read(path string) ([]byte, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var stat, err = os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+
+	if f.TTL > 0 && time.Since(stat.ModTime()) > f.TTL {
+		return nil, false
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	// Bump mtime on every hit so evict's LRU sort protects hot entries,
+	// not just recently-written ones.
+	var now = time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return body, true
+}
+
+func (f *FileCache) write(path string, body []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := atomicWriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+
+	return f.evict()
+}
+
+// atomicWriteFile writes body to a temp file in path's directory and
+// renames it into place, so a concurrent reader of path never observes a
+// partially written file.
+func atomicWriteFile(path string, body []byte, perm os.FileMode) error {
+	var tmp, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	var tmpPath = tmp.Name()
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// evict removes the least-recently-used entries (by mtime, which read
+// bumps on every hit) until the cache directory's total size is at or
+// below MaxBytes. Callers must hold f.mu for writing.
+func (f *FileCache) evict() error {
+	if f.MaxBytes <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(f.Dir)
+	if err != nil {
+		return err
+	}
+
+	type fileInfo struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files = make([]fileInfo, 0, len(entries))
+	var total int64
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		var path = filepath.Join(f.Dir, e.Name())
+		files = append(files, fileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if total <= f.MaxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, fi := range files {
+		if total <= f.MaxBytes {
+			break
+		}
+		if err := os.Remove(fi.path); err != nil {
+			continue
+		}
+		total -= fi.size
+	}
+
+	return nil
+}
+
+func hashKey(key string) string {
+	var sum = sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
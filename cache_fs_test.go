@@ -0,0 +1,152 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"image"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCacheResolvedRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	var want = &ImageData{
+		URL:         "https://example.com/cat.jpg",
+		Extension:   "jpeg",
+		Config:      image.Config{ColorModel: nil, Width: 800, Height: 600},
+		Area:        800 * 600,
+		FileSize:    12345,
+		Orientation: OrientationRotate90,
+		EXIF:        map[string]any{"Make": "Canon"},
+	}
+
+	require.NoError(t, cache.PutResolved("key", want))
+
+	got, ok := cache.GetResolved("key")
+	require.True(t, ok, "expected a cache hit after PutResolved")
+
+	assert.Equal(t, want.URL, got.URL)
+	assert.Equal(t, want.Extension, got.Extension)
+	assert.Equal(t, want.Config.Width, got.Config.Width)
+	assert.Equal(t, want.Config.Height, got.Config.Height)
+	assert.Equal(t, want.Area, got.Area)
+	assert.Equal(t, want.FileSize, got.FileSize)
+	assert.Equal(t, want.Orientation, got.Orientation)
+	assert.Equal(t, want.EXIF, got.EXIF)
+}
+
+func TestFileCacheGetResolvedMiss(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	_, ok := cache.GetResolved("missing")
+	assert.False(t, ok)
+}
+
+func TestFileCacheBytesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.PutBytes("key", []byte("pixels")))
+
+	got, ok := cache.GetBytes("key")
+	require.True(t, ok)
+	assert.Equal(t, []byte("pixels"), got)
+}
+
+func TestFileCacheTTLExpiry(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 10*time.Millisecond, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.PutBytes("key", []byte("data")))
+
+	_, ok := cache.GetBytes("key")
+	require.True(t, ok, "expected a hit before TTL elapses")
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, ok = cache.GetBytes("key")
+	assert.False(t, ok, "expected a miss once TTL has elapsed")
+}
+
+// TestFileCacheEvictionProtectsRecentlyRead reproduces the scenario this
+// request's LRU eviction promise depends on: an entry that's read
+// repeatedly must survive eviction over one that was written once and
+// never touched again, even though the latter was written more recently.
+func TestFileCacheEvictionProtectsRecentlyRead(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 0, 25)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.PutBytes("a", bytes.Repeat([]byte("a"), 10)))
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, cache.PutBytes("b", bytes.Repeat([]byte("b"), 10)))
+
+	for i := 0; i < 5; i++ {
+		time.Sleep(time.Millisecond)
+		_, ok := cache.GetBytes("a")
+		require.True(t, ok)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, cache.PutBytes("c", bytes.Repeat([]byte("c"), 10)))
+
+	_, aOK := cache.GetBytes("a")
+	_, bOK := cache.GetBytes("b")
+	_, cOK := cache.GetBytes("c")
+
+	assert.True(t, aOK, "a was read repeatedly and should survive eviction")
+	assert.False(t, bOK, "b was never touched after its initial write and should be evicted")
+	assert.True(t, cOK, "c was just written and should survive eviction")
+}
+
+// TestFileCacheConcurrentAccessNoTornReads guards against the write path
+// regressing back to a non-atomic os.WriteFile: a reader racing a writer
+// on the same key must see either the old body or the new one in full,
+// never a length in between.
+func TestFileCacheConcurrentAccessNoTornReads(t *testing.T) {
+	t.Parallel()
+
+	var cache, err = NewFileCache(t.TempDir(), 0, 0)
+	require.NoError(t, err)
+
+	var short = []byte("short")
+	var long = bytes.Repeat([]byte("x"), 4096)
+	require.NoError(t, cache.PutBytes("key", short))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			var body = short
+			if i%2 == 0 {
+				body = long
+			}
+			_ = cache.PutBytes("key", body)
+		}(i)
+		go func() {
+			defer wg.Done()
+			body, ok := cache.GetBytes("key")
+			if !ok {
+				return
+			}
+			assert.True(t, len(body) == len(short) || len(body) == len(long), "torn read: got %d bytes", len(body))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,226 @@
+package imageupsizer
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ClientConfig tunes Client's timeouts, retry behavior, rate limiting, and
+// outgoing identity.
+type ClientConfig struct {
+	// RequestTimeout bounds each individual HTTP round trip.
+	RequestTimeout time.Duration
+
+	// MaxRetries is how many additional attempts a retryable GET gets
+	// after its first failure.
+	MaxRetries int
+
+	// RetryBackoffBase is the delay before the first retry; each
+	// subsequent retry doubles it, plus up to RetryBackoffJitter of
+	// random jitter.
+	RetryBackoffBase   time.Duration
+	RetryBackoffJitter time.Duration
+
+	// RateLimit caps outgoing requests per second, per host. Zero means
+	// unlimited.
+	RateLimit float64
+
+	// UserAgents are chosen round-robin for each outgoing request. An
+	// empty slice leaves the request's existing User-Agent header alone.
+	UserAgents []string
+
+	// Transport is used in place of http.DefaultTransport, e.g. to set a
+	// proxy.
+	Transport http.RoundTripper
+}
+
+// DefaultClientConfig returns sane defaults: a generous timeout, a
+// handful of retries with exponential backoff, a conservative per-host
+// rate limit, and the scraper's original user agents rotated between
+// requests.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		RequestTimeout:     30 * time.Second,
+		MaxRetries:         3,
+		RetryBackoffBase:   500 * time.Millisecond,
+		RetryBackoffJitter: 250 * time.Millisecond,
+		RateLimit:          1,
+		UserAgents: []string{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/101.0.4951.54 Safari/537.36",
+			"Mozilla/5.0 (X11; Linux x86_64; rv:101.0) Gecko/20100101 Firefox/101.0",
+		},
+	}
+}
+
+// Client performs this package's outgoing HTTP requests with a timeout,
+// retries with backoff on transient failures, per-host rate limiting, and
+// rotating user agents. The package-level helpers (getImage and each
+// Provider's upload/search requests) go through DefaultClient so callers
+// who don't need custom settings keep working unchanged.
+type Client struct {
+	config     ClientConfig
+	httpClient *http.Client
+	uaIndex    uint32
+
+	mu       sync.Mutex
+	limiters map[string]*tokenBucket
+}
+
+// NewClient builds a Client from cfg.
+func NewClient(cfg ClientConfig) *Client {
+	return &Client{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout:   cfg.RequestTimeout,
+			Transport: cfg.Transport,
+		},
+		limiters: make(map[string]*tokenBucket),
+	}
+}
+
+// DefaultClient is used by every package-level helper that doesn't take
+// an explicit Client.
+var DefaultClient = NewClient(DefaultClientConfig())
+
+// Do executes req, rotating the User-Agent header, rate limiting per
+// host, and retrying idempotent GETs that fail with a 5xx or 429 (honoring
+// any Retry-After header) up to config.MaxRetries times. A response whose
+// body already contains a captcha challenge is a successful 2xx fetch, not
+// a transient failure, so it is returned as-is without retrying.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if len(c.config.UserAgents) > 0 {
+		var i = atomic.AddUint32(&c.uaIndex, 1) - 1
+		req.Header.Set("User-Agent", c.config.UserAgents[i%uint32(len(c.config.UserAgents))])
+	}
+
+	if err := c.throttle(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	var retryable = req.Method == http.MethodGet
+	var resp, err = c.httpClient.Do(req)
+
+	for attempt := 0; retryable && attempt < c.config.MaxRetries && shouldRetry(resp, err); attempt++ {
+		var wait = retryAfter(resp)
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+
+		if err := c.throttle(req.Context(), req.URL.Host); err != nil {
+			return nil, err
+		}
+		resp, err = c.httpClient.Do(req)
+	}
+
+	return resp, err
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	var header = resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	var seconds, err = strconv.Atoi(header)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	var delay = c.config.RetryBackoffBase << attempt
+	if c.config.RetryBackoffJitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(c.config.RetryBackoffJitter)))
+	}
+	return delay
+}
+
+// throttle blocks until host's rate limit allows another request, or
+// returns ctx's error if ctx is done first.
+func (c *Client) throttle(ctx context.Context, host string) error {
+	if c.config.RateLimit <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	var bucket, ok = c.limiters[host]
+	if !ok {
+		bucket = newTokenBucket(c.config.RateLimit)
+		c.limiters[host] = bucket
+	}
+	c.mu.Unlock()
+
+	return bucket.take(ctx)
+}
+
+// tokenBucket is a minimal per-host rate limiter: it refills at rate
+// tokens/sec and blocks callers until at least one token is available.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// take blocks until a token is available, or returns ctx's error if ctx
+// is canceled first. A token consumed right before a canceled wait is not
+// refunded: the caller was never going to use it, and the next caller's
+// wait is computed from b.last regardless.
+func (b *tokenBucket) take(ctx context.Context) error {
+	b.mu.Lock()
+
+	var now = time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		var wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.tokens = 0
+		b.last = now.Add(wait)
+		b.mu.Unlock()
+
+		var timer = time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+
+	b.tokens--
+	b.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,164 @@
+package imageupsizer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(t *testing.T, maxRetries int) *Client {
+	t.Helper()
+	return NewClient(ClientConfig{
+		RequestTimeout:     time.Second,
+		MaxRetries:         maxRetries,
+		RetryBackoffBase:   time.Millisecond,
+		RetryBackoffJitter: 0,
+	})
+}
+
+func TestClientRetriesOn5xxThenSucceeds(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := testClient(t, 3).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestClientGivesUpAfterMaxRetries(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	var req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := testClient(t, 2).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	// One initial attempt plus MaxRetries retries.
+	assert.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+}
+
+func TestClientHonorsRetryAfterOverBackoff(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	// RetryBackoffBase is deliberately huge: if the client fell back to
+	// its own backoff instead of honoring Retry-After, this test would
+	// take minutes instead of ~1s.
+	var client = NewClient(ClientConfig{
+		RequestTimeout:   time.Second,
+		MaxRetries:       1,
+		RetryBackoffBase: time.Minute,
+	})
+
+	var start = time.Now()
+	resp, err := client.Do(req)
+	var elapsed = time.Since(start)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Less(t, elapsed, 5*time.Second, "should have waited ~1s for Retry-After, not a minute of backoff")
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond, "should have waited out the full Retry-After")
+}
+
+func TestClientDoesNotRetryNonGET(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var req, err = http.NewRequest(http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := testClient(t, 3).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "non-GET requests should never be retried")
+}
+
+func TestClientDoesNotRetryOrdinaryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts int32
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := testClient(t, 3).Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts), "a plain 404 is not a transient failure")
+}
+
+func TestTokenBucketTakeRespectsContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	// A tiny rate means the initial token balance (== rate) is under 1,
+	// so this call must wait -- giving the canceled context a chance to
+	// win the select instead of the refill timer.
+	var bucket = newTokenBucket(0.001)
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	var err = bucket.take(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
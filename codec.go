@@ -0,0 +1,70 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+)
+
+// encodeImage writes img to w using the codec named by ext, defaulting to
+// JPEG for anything it doesn't recognize.
+func encodeImage(w io.Writer, img image.Image, ext string) error {
+	switch ext {
+	case "png":
+		return png.Encode(w, img)
+	default:
+		return jpeg.Encode(w, img, nil)
+	}
+}
+
+const (
+	jpegSOI       = 0xFFD8
+	jpegAPP1      = 0xFFE1
+	exifHeaderLen = 6 // "Exif\x00\x00"
+)
+
+// embedEXIF returns a copy of jpegBytes with an APP1 segment carrying raw
+// (the TIFF bytes goexif.Exif.Raw produced) inserted right after the
+// Start-Of-Image marker. It returns jpegBytes unchanged if raw is empty or
+// jpegBytes doesn't start with a JPEG SOI marker.
+func embedEXIF(jpegBytes []byte, raw []byte) []byte {
+	if len(raw) == 0 || len(jpegBytes) < 2 || binary.BigEndian.Uint16(jpegBytes[:2]) != jpegSOI {
+		return jpegBytes
+	}
+
+	var segment bytes.Buffer
+	segment.Write([]byte{0xFF, byte(jpegAPP1 & 0xFF)})
+	var length = make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(2+exifHeaderLen+len(raw)))
+	segment.Write(length)
+	segment.WriteString("Exif\x00\x00")
+	segment.Write(raw)
+
+	var out bytes.Buffer
+	out.Write(jpegBytes[:2])
+	out.Write(segment.Bytes())
+	out.Write(jpegBytes[2:])
+
+	return out.Bytes()
+}
+
+// WriteFile writes d.Bytes to path, re-embedding d's original EXIF block
+// if it has one and d.Extension is "jpeg" or "jpg", so photographers don't lose rotation
+// and capture metadata when saving an upsized or normalized result.
+func (d *ImageData) WriteFile(path string) error {
+	if len(d.Bytes) == 0 {
+		return errors.New("imageupsizer: ImageData has no Bytes to write")
+	}
+
+	var body = d.Bytes
+	if d.Extension == "jpeg" || d.Extension == "jpg" {
+		body = embedEXIF(body, d.rawEXIF)
+	}
+
+	return os.WriteFile(path, body, 0o644)
+}
@@ -0,0 +1,17 @@
+package imageupsizer
+
+import "errors"
+
+var (
+	// ErrCaptcha is returned when a provider's response indicates we were
+	// served a captcha challenge instead of search results.
+	ErrCaptcha = errors.New("captcha encountered")
+
+	// ErrNoLargerAvailable is returned when a provider found the image but
+	// could not locate a result larger than the one it was given.
+	ErrNoLargerAvailable = errors.New("no larger image available")
+
+	// ErrNoResults is returned when a provider's reverse image search
+	// returned no results at all.
+	ErrNoResults = errors.New("no results found")
+)
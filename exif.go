@@ -0,0 +1,219 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	goexif "github.com/rwcarlsen/goexif/exif"
+	"github.com/rwcarlsen/goexif/tiff"
+)
+
+// Orientation mirrors the EXIF "Orientation" tag: OrientationNormal means
+// no correction is needed, the rest describe some combination of rotation
+// and mirroring a viewer (or NormalizeOrientation) should apply first.
+type Orientation int
+
+const (
+	OrientationNormal         Orientation = 1
+	OrientationFlipHorizontal Orientation = 2
+	OrientationRotate180      Orientation = 3
+	OrientationFlipVertical   Orientation = 4
+	OrientationTranspose      Orientation = 5
+	OrientationRotate90       Orientation = 6
+	OrientationTransverse     Orientation = 7
+	OrientationRotate270      Orientation = 8
+)
+
+// exifWalker collects every tag goexif parses into a plain map, keyed by
+// its EXIF field name, so callers don't need to depend on goexif's types.
+type exifWalker map[string]any
+
+func (w exifWalker) Walk(name goexif.FieldName, tag *tiff.Tag) error {
+	w[string(name)] = tag.String()
+	return nil
+}
+
+// readEXIF best-effort parses data's EXIF block, returning its
+// orientation, a flattened tag map, and the raw TIFF bytes (suitable for
+// re-embedding verbatim into another JPEG later). A nil rawEXIF means no
+// EXIF block was found or it failed to parse, which is expected for
+// formats like PNG and not treated as an error.
+func readEXIF(data []byte) (Orientation, map[string]any, []byte) {
+	x, err := goexif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return OrientationNormal, nil, nil
+	}
+
+	var orientation = OrientationNormal
+	if tag, err := x.Get(goexif.Orientation); err == nil {
+		if v, err := tag.Int(0); err == nil {
+			orientation = Orientation(v)
+		}
+	}
+
+	var fields = make(exifWalker)
+	_ = x.Walk(fields)
+
+	return orientation, fields, x.Raw
+}
+
+// NormalizeOrientation rotates/flips d's decoded image so its pixels match
+// what the EXIF Orientation tag says should be displayed, then resets
+// Orientation to OrientationNormal so later area/aspect comparisons don't
+// need to account for it. It's a no-op if d has no Bytes or Orientation is
+// already OrientationNormal.
+func (d *ImageData) NormalizeOrientation() error {
+	if len(d.Bytes) == 0 || d.Orientation == OrientationNormal {
+		return nil
+	}
+
+	img, ext, err := image.Decode(bytes.NewReader(d.Bytes))
+	if err != nil {
+		return err
+	}
+
+	var oriented = applyOrientation(img, d.Orientation)
+
+	var buf bytes.Buffer
+	if err := encodeImage(&buf, oriented, ext); err != nil {
+		return err
+	}
+
+	d.Bytes = buf.Bytes()
+	d.Extension = ext
+	d.Config = image.Config{ColorModel: oriented.ColorModel(), Width: oriented.Bounds().Dx(), Height: oriented.Bounds().Dy()}
+	d.Area = d.Config.Width * d.Config.Height
+	d.FileSize = int64(len(d.Bytes))
+	d.Orientation = OrientationNormal
+	d.rawEXIF = normalizeOrientationTag(d.rawEXIF)
+
+	return nil
+}
+
+const (
+	orientationTagID   = 0x0112
+	orientationTagType = 3 // SHORT
+)
+
+// normalizeOrientationTag returns a copy of raw (a goexif.Exif.Raw TIFF
+// block) with its Orientation tag's value rewritten to OrientationNormal,
+// so that re-embedding raw after NormalizeOrientation has already baked
+// the rotation into the pixels doesn't cause viewers to apply it twice.
+// It returns raw unchanged if the tag can't be found or raw is too short
+// to be a valid TIFF block.
+func normalizeOrientationTag(raw []byte) []byte {
+	if len(raw) < 8 {
+		return raw
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(raw, []byte("II")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(raw, []byte("MM")):
+		order = binary.BigEndian
+	default:
+		return raw
+	}
+
+	var ifdOffset = order.Uint32(raw[4:8])
+	if int(ifdOffset)+2 > len(raw) {
+		return raw
+	}
+
+	var out = append([]byte(nil), raw...)
+	var count = order.Uint16(out[ifdOffset : ifdOffset+2])
+	for i := 0; i < int(count); i++ {
+		var entry = int(ifdOffset) + 2 + i*12
+		if entry+12 > len(out) {
+			break
+		}
+		var tag = order.Uint16(out[entry : entry+2])
+		var typ = order.Uint16(out[entry+2 : entry+4])
+		if tag == orientationTagID && typ == orientationTagType {
+			order.PutUint16(out[entry+8:entry+10], uint16(OrientationNormal))
+			break
+		}
+	}
+
+	return out
+}
+
+// applyOrientation returns a copy of img with the rotation/flip described
+// by o applied.
+func applyOrientation(img image.Image, o Orientation) image.Image {
+	switch o {
+	case OrientationFlipHorizontal:
+		return flipHorizontal(img)
+	case OrientationRotate180:
+		return rotate180(img)
+	case OrientationFlipVertical:
+		return flipVertical(img)
+	case OrientationTranspose:
+		return flipHorizontal(rotate90(img))
+	case OrientationRotate90:
+		return rotate90(img)
+	case OrientationTransverse:
+		return flipHorizontal(rotate270(img))
+	case OrientationRotate270:
+		return rotate270(img)
+	default:
+		return img
+	}
+}
+
+func rotate90(img image.Image) image.Image {
+	var b = img.Bounds()
+	var dst = image.NewNRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.Y-1-y, x, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func rotate180(img image.Image) image.Image {
+	var b = img.Bounds()
+	var dst = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.X-1-x, b.Max.Y-1-y, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func rotate270(img image.Image) image.Image {
+	var b = img.Bounds()
+	var dst = image.NewNRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(y, b.Max.X-1-x, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func flipHorizontal(img image.Image) image.Image {
+	var b = img.Bounds()
+	var dst = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(b.Max.X-1-x, y, img.At(x, y))
+		}
+	}
+	return dst
+}
+
+func flipVertical(img image.Image) image.Image {
+	var b = img.Bounds()
+	var dst = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.Set(x, b.Max.Y-1-y, img.At(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,148 @@
+package imageupsizer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newLabeledImage builds a w x h gray image where pixel (x, y) has value
+// y*w+x, so a transform's output pixel values reveal exactly how it moved
+// each source pixel.
+func newLabeledImage(w, h int) *image.Gray {
+	var img = image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(y*w + x)})
+		}
+	}
+	return img
+}
+
+func grayAt(t *testing.T, img image.Image, x, y int) uint8 {
+	t.Helper()
+	var c, ok = img.At(x, y).(color.Gray)
+	if !ok {
+		var r, _, _, _ = img.At(x, y).RGBA()
+		return uint8(r)
+	}
+	return c.Y
+}
+
+func TestApplyOrientation(t *testing.T) {
+	t.Parallel()
+
+	// 3x2 source: label(x,y) = y*3+x
+	//   0 1 2
+	//   3 4 5
+	var src = newLabeledImage(3, 2)
+
+	tests := []struct {
+		name        string
+		orientation Orientation
+		wantW       int
+		wantH       int
+		// wantAt maps an output (x, y) to the label it should carry.
+		wantAt map[[2]int]uint8
+	}{
+		{
+			name:        "normal is a no-op",
+			orientation: OrientationNormal,
+			wantW:       3, wantH: 2,
+			wantAt: map[[2]int]uint8{{0, 0}: 0, {2, 1}: 5},
+		},
+		{
+			name:        "flip horizontal mirrors left-right",
+			orientation: OrientationFlipHorizontal,
+			wantW:       3, wantH: 2,
+			wantAt: map[[2]int]uint8{{0, 0}: 2, {2, 0}: 0, {0, 1}: 5, {2, 1}: 3},
+		},
+		{
+			name:        "rotate180 mirrors both axes",
+			orientation: OrientationRotate180,
+			wantW:       3, wantH: 2,
+			wantAt: map[[2]int]uint8{{0, 0}: 5, {2, 1}: 0},
+		},
+		{
+			name:        "flip vertical mirrors top-bottom",
+			orientation: OrientationFlipVertical,
+			wantW:       3, wantH: 2,
+			wantAt: map[[2]int]uint8{{0, 0}: 3, {2, 0}: 5, {0, 1}: 0, {2, 1}: 2},
+		},
+		{
+			name:        "rotate90 turns 3x2 into 2x3",
+			orientation: OrientationRotate90,
+			wantW:       2, wantH: 3,
+			// out[r][c] = src[1-c][r]: a 90 degree clockwise turn.
+			wantAt: map[[2]int]uint8{{0, 0}: 3, {1, 0}: 0, {0, 2}: 5, {1, 2}: 2},
+		},
+		{
+			name:        "rotate270 turns 3x2 into 2x3",
+			orientation: OrientationRotate270,
+			wantW:       2, wantH: 3,
+			wantAt: map[[2]int]uint8{{0, 0}: 2, {1, 0}: 5, {0, 2}: 0, {1, 2}: 3},
+		},
+		{
+			name:        "transpose is the main-diagonal transpose: out[c][r] = src[r][c]",
+			orientation: OrientationTranspose,
+			wantW:       2, wantH: 3,
+			wantAt: map[[2]int]uint8{{0, 0}: 0, {1, 0}: 3, {0, 1}: 1, {1, 1}: 4, {0, 2}: 2, {1, 2}: 5},
+		},
+		{
+			name:        "transverse is the anti-diagonal reflection",
+			orientation: OrientationTransverse,
+			wantW:       2, wantH: 3,
+			wantAt: map[[2]int]uint8{{0, 0}: 5, {1, 0}: 2, {0, 2}: 3, {1, 2}: 0},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out = applyOrientation(src, tc.orientation)
+			assert.Equal(t, tc.wantW, out.Bounds().Dx())
+			assert.Equal(t, tc.wantH, out.Bounds().Dy())
+
+			for at, want := range tc.wantAt {
+				assert.Equal(t, want, grayAt(t, out, at[0], at[1]), "pixel (%d,%d)", at[0], at[1])
+			}
+		})
+	}
+}
+
+func TestNormalizeOrientationTagRewritesToNormal(t *testing.T) {
+	t.Parallel()
+
+	// Minimal little-endian TIFF block: header + one IFD entry for
+	// Orientation (tag 0x0112, type SHORT, count 1, value 6 = Rotate90).
+	var raw = []byte{
+		'I', 'I', 42, 0, // byte order + magic
+		8, 0, 0, 0, // IFD0 offset
+		1, 0, // entry count
+		0x12, 0x01, // tag = Orientation
+		3, 0, // type = SHORT
+		1, 0, 0, 0, // count = 1
+		6, 0, 0, 0, // value = 6 (Rotate90), padded to 4 bytes
+	}
+
+	var out = normalizeOrientationTag(raw)
+	assert.Equal(t, uint16(OrientationNormal), binaryLEUint16(out[18:20]))
+
+	// Untouched input is never mutated in place.
+	assert.Equal(t, uint16(6), binaryLEUint16(raw[18:20]))
+}
+
+func TestNormalizeOrientationTagIgnoresGarbage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []byte("short"), normalizeOrientationTag([]byte("short")))
+	assert.Nil(t, normalizeOrientationTag(nil))
+}
+
+func binaryLEUint16(b []byte) uint16 {
+	return uint16(b[0]) | uint16(b[1])<<8
+}
@@ -0,0 +1,199 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GoogleProvider searches Google Images by driving the "search by image"
+// upload flow. It is the original, single-provider implementation this
+// package shipped before Provider/MetaSearch existed.
+type GoogleProvider struct{}
+
+func (GoogleProvider) Name() string { return "google" }
+
+func (g GoogleProvider) SearchByFile(ctx context.Context, path string) ([]ImageData, error) {
+	contents, err := g.uploadImage(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return g.parseResults(ctx, contents)
+}
+
+func (g GoogleProvider) SearchByURL(ctx context.Context, imageURL string) ([]ImageData, error) {
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://images.google.com/searchbyimage?image_url="+url.QueryEscape(imageURL), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("origin", "https://images.google.com/")
+	req.Header.Add("referer", "https://images.google.com/")
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.parseResults(ctx, contents)
+}
+
+func (g GoogleProvider) uploadImage(ctx context.Context, filename string) ([]byte, error) {
+	var file, err = os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileContents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf = new(bytes.Buffer)
+	var writer = multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("encoded_image", filename)
+	if err != nil {
+		return nil, err
+	}
+	_, err = part.Write(fileContents)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.WriteField("image_url", ""); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteField("filename", ""); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteField("hl", "en"); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://images.google.com/searchbyimage/upload", buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	req.Header.Add("origin", "https://images.google.com/")
+	req.Header.Add("referer", "https://images.google.com/")
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return contents, nil
+}
+
+// parseResults follows the "isz:l" (large) results link out of the upload
+// response, then scrapes every [url, height, width] tuple embedded in that
+// page's JS data blob.
+func (g GoogleProvider) parseResults(ctx context.Context, contents []byte) ([]ImageData, error) {
+	var largeImgURL string
+	var r, err = regexp.Compile(`(/search\?.*?simg:.*?)">`)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range r.FindAllStringSubmatch(string(contents), -1) {
+		if len(i) < 2 {
+			continue
+		}
+
+		if strings.Contains(i[1], ",isz:l") {
+			largeImgURL = "https://google.com" + html.UnescapeString(i[1])
+			break
+		}
+	}
+
+	if len(largeImgURL) == 0 && bytes.Contains(contents, []byte("captcha")) {
+		return nil, ErrCaptcha
+	} else if len(largeImgURL) == 0 {
+		return nil, ErrNoLargerAvailable
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, largeImgURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("origin", "https://images.google.com/")
+	req.Header.Add("referer", "https://images.google.com/")
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	imgInfo, err := regexp.Compile(`\["(https://.*?.)",(\d+),(\d+)\]`)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []ImageData
+	for _, i := range imgInfo.FindAllStringSubmatch(string(body), -1) {
+		if len(i) < 4 {
+			continue
+		}
+
+		urlUnquote, err := strconv.Unquote("\"" + i[1] + "\"")
+		if err != nil {
+			continue
+		}
+
+		imgURL, err := url.Parse(urlUnquote)
+		if err != nil {
+			continue
+		}
+
+		imgHeight, err := strconv.Atoi(i[2])
+		if err != nil {
+			continue
+		}
+
+		imgWidth, err := strconv.Atoi(i[3])
+		if err != nil {
+			continue
+		}
+
+		data = append(data, ImageData{
+			URL:  imgURL.String(),
+			Area: imgHeight * imgWidth,
+		})
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoResults
+	}
+
+	return data, nil
+}
@@ -0,0 +1,154 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// getImage downloads url and decodes just enough of it to populate an
+// ImageData's dimensions, keeping the full body around so callers don't
+// have to fetch it twice. When cache is non-nil its bytes are consulted
+// before hitting the network and populated after a successful fetch.
+func getImage(ctx context.Context, url string, cache Cache) (*ImageData, error) {
+	if cache != nil {
+		if body, ok := cache.GetBytes(url); ok {
+			return decodeImageData(url, body)
+		}
+	}
+
+	var data = &ImageData{}
+
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
+	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("non 2xx resp code: %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("content-type"), "text/html") {
+		return nil, errors.New("resp was html: " + url)
+	}
+
+	imageDecode, ext, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	data.URL = url
+	data.Bytes = body
+	data.Extension = ext
+	data.Config = imageDecode
+	data.Area = data.Config.Height * data.Config.Width
+	data.FileSize = int64(len(body))
+
+	if cache != nil {
+		_ = cache.PutBytes(url, body)
+	}
+
+	return data, nil
+}
+
+// getImageStream downloads url and copies its body into w in a single
+// pass, decoding just the header along the way via an io.TeeReader so the
+// response is never buffered into a []byte. The returned ImageData's
+// Bytes field is left empty; the image itself lives wherever w put it.
+func getImageStream(ctx context.Context, url string, w io.Writer) (*ImageData, error) {
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
+	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("non 2xx resp code: %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("content-type"), "text/html") {
+		return nil, errors.New("resp was html: " + url)
+	}
+
+	var pr, pw = io.Pipe()
+	var tee = io.TeeReader(resp.Body, pw)
+
+	var config image.Config
+	var ext string
+	var decodeErr error
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		config, ext, decodeErr = image.DecodeConfig(pr)
+		// DecodeConfig only reads the header, so drain the rest of the
+		// pipe ourselves or the io.Copy below would block forever
+		// waiting for a reader.
+		io.Copy(io.Discard, pr)
+	}()
+
+	fileSize, err := io.Copy(w, tee)
+	pw.Close()
+	<-done
+
+	if err != nil {
+		return nil, err
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return &ImageData{
+		URL:       url,
+		Extension: ext,
+		Config:    config,
+		Area:      config.Width * config.Height,
+		FileSize:  fileSize,
+	}, nil
+}
+
+// decodeImageData builds an ImageData from already-downloaded bytes, used
+// to serve cache hits without touching the network.
+func decodeImageData(url string, body []byte) (*ImageData, error) {
+	imageDecode, ext, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageData{
+		URL:       url,
+		Bytes:     body,
+		Extension: ext,
+		Config:    imageDecode,
+		Area:      imageDecode.Height * imageDecode.Width,
+		FileSize:  int64(len(body)),
+	}, nil
+}
@@ -0,0 +1,172 @@
+package imageupsizer
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// imageFetchConcurrency bounds how many candidate images MetaSearch will
+// download at once while hashing results for dedup.
+const imageFetchConcurrency = 8
+
+// MetaSearch fans a single search out across multiple Providers
+// concurrently and merges their candidates into one deduplicated,
+// largest-first list. It implements Provider itself, so it can be used
+// anywhere a single provider would go.
+type MetaSearch struct {
+	Providers []Provider
+
+	// HashDistance is the maximum hamming distance between two
+	// candidates' perceptual hashes for them to be treated as duplicates.
+	// Zero uses defaultHashDistance.
+	HashDistance int
+}
+
+func (m MetaSearch) Name() string { return "metasearch" }
+
+func (m MetaSearch) SearchByFile(ctx context.Context, path string) ([]ImageData, error) {
+	return m.fanOut(ctx, func(p Provider) ([]ImageData, error) {
+		return p.SearchByFile(ctx, path)
+	})
+}
+
+func (m MetaSearch) SearchByURL(ctx context.Context, imageURL string) ([]ImageData, error) {
+	return m.fanOut(ctx, func(p Provider) ([]ImageData, error) {
+		return p.SearchByURL(ctx, imageURL)
+	})
+}
+
+func (m MetaSearch) fanOut(ctx context.Context, search func(Provider) ([]ImageData, error)) ([]ImageData, error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var merged []ImageData
+
+	wg.Add(len(m.Providers))
+	for _, p := range m.Providers {
+		go func(p Provider) {
+			defer wg.Done()
+
+			results, err := search(p)
+			if err != nil {
+				logrus.WithField("provider", p.Name()).WithError(err).Warn("provider search failed")
+				return
+			}
+
+			mu.Lock()
+			merged = append(merged, results...)
+			mu.Unlock()
+		}(p)
+	}
+	wg.Wait()
+
+	merged = dedupeByURL(merged)
+	merged = m.dedupeByHash(ctx, merged)
+
+	if len(merged) == 0 {
+		return nil, ErrNoResults
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Area > merged[j].Area
+	})
+
+	return merged, nil
+}
+
+// dedupeByURL keeps the first candidate seen for each distinct URL.
+func dedupeByURL(candidates []ImageData) []ImageData {
+	var seen = make(map[string]bool, len(candidates))
+	var deduped = make([]ImageData, 0, len(candidates))
+
+	for _, c := range candidates {
+		if seen[c.URL] {
+			continue
+		}
+		seen[c.URL] = true
+		deduped = append(deduped, c)
+	}
+
+	return deduped
+}
+
+// dedupeByHash downloads each candidate (bounded by imageFetchConcurrency),
+// hashes it, and drops any candidate whose hash is within HashDistance of
+// one already kept, favoring the larger of the two. Candidates that fail
+// to download or hash are kept as-is since there's nothing to compare.
+func (m MetaSearch) dedupeByHash(ctx context.Context, candidates []ImageData) []ImageData {
+	var threshold = m.HashDistance
+	if threshold == 0 {
+		threshold = defaultHashDistance
+	}
+
+	var sem = make(chan struct{}, imageFetchConcurrency)
+	var wg sync.WaitGroup
+	var fetched = make([]ImageData, len(candidates))
+	var hashes = make([]uint64, len(candidates))
+	var hashed = make([]bool, len(candidates))
+
+	wg.Add(len(candidates))
+	for i, c := range candidates {
+		go func(i int, c ImageData) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			full, err := getImage(ctx, c.URL, nil)
+			if err != nil {
+				fetched[i] = c
+				return
+			}
+
+			hash, err := perceptualHash(full.Bytes)
+			if err != nil {
+				fetched[i] = *full
+				return
+			}
+
+			fetched[i] = *full
+			hashes[i] = hash
+			hashed[i] = true
+		}(i, c)
+	}
+	wg.Wait()
+
+	type hashedCandidate struct {
+		data ImageData
+		hash uint64
+	}
+	var withHash []hashedCandidate
+	var withoutHash []ImageData
+
+	for i, c := range fetched {
+		if !hashed[i] {
+			withoutHash = append(withoutHash, c)
+			continue
+		}
+
+		var dupeOf = -1
+		for j := range withHash {
+			if hammingDistance(hashes[i], withHash[j].hash) <= threshold {
+				dupeOf = j
+				break
+			}
+		}
+
+		if dupeOf == -1 {
+			withHash = append(withHash, hashedCandidate{data: c, hash: hashes[i]})
+		} else if c.Area > withHash[dupeOf].data.Area {
+			withHash[dupeOf].data = c
+		}
+	}
+
+	var kept = make([]ImageData, 0, len(withHash)+len(withoutHash))
+	for _, hc := range withHash {
+		kept = append(kept, hc.data)
+	}
+	kept = append(kept, withoutHash...)
+
+	return kept
+}
@@ -0,0 +1,51 @@
+package imageupsizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeByURL(t *testing.T) {
+	t.Parallel()
+
+	var candidates = []ImageData{
+		{URL: "https://a.example.com/1.jpg", Area: 100},
+		{URL: "https://b.example.com/2.jpg", Area: 200},
+		{URL: "https://a.example.com/1.jpg", Area: 100}, // exact duplicate
+	}
+
+	var deduped = dedupeByURL(candidates)
+
+	assert.Len(t, deduped, 2)
+	assert.Equal(t, "https://a.example.com/1.jpg", deduped[0].URL)
+	assert.Equal(t, "https://b.example.com/2.jpg", deduped[1].URL)
+}
+
+func TestDedupeByURLEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, dedupeByURL(nil))
+}
+
+func TestHammingDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a, b uint64
+		want int
+	}{
+		{name: "identical hashes", a: 0b1010, b: 0b1010, want: 0},
+		{name: "single bit differs", a: 0b1010, b: 0b1011, want: 1},
+		{name: "all bits differ", a: 0, b: ^uint64(0), want: 64},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, hammingDistance(tc.a, tc.b))
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// defaultHashDistance is the maximum hamming distance between two
+// perceptual hashes for them to be treated as the same image.
+const defaultHashDistance = 8
+
+// perceptualHash computes an 8x8 average hash (aHash) of the image encoded
+// in data, good enough to spot near-identical results different providers
+// return for the same underlying photo.
+func perceptualHash(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	var small = image.NewGray(image.Rect(0, 0, 8, 8))
+	draw.BiLinear.Scale(small, small.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	var sum int
+	var pixels [64]byte
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			var gray = small.GrayAt(x, y).Y
+			pixels[y*8+x] = gray
+			sum += int(gray)
+		}
+	}
+	var avg = byte(sum / 64)
+
+	var hash uint64
+	for i, p := range pixels {
+		if p >= avg {
+			hash |= 1 << uint(i)
+		}
+	}
+
+	return hash, nil
+}
+
+// hammingDistance returns the number of differing bits between two hashes.
+func hammingDistance(a, b uint64) int {
+	var x = a ^ b
+	var count int
+	for x != 0 {
+		count++
+		x &= x - 1
+	}
+	return count
+}
@@ -0,0 +1,20 @@
+package imageupsizer
+
+import "context"
+
+// Provider is a reverse image search backend. Implementations upload a
+// local file or point the backend at an already-hosted URL and return
+// every candidate match they can parse out of the response. Candidates
+// are returned unsorted; callers that care about size compare
+// ImageData.Area themselves.
+type Provider interface {
+	// Name identifies the provider for logging and result attribution.
+	Name() string
+
+	// SearchByFile uploads the image at path and returns candidate matches.
+	SearchByFile(ctx context.Context, path string) ([]ImageData, error)
+
+	// SearchByURL points the backend at an already-hosted image and returns
+	// candidate matches.
+	SearchByURL(ctx context.Context, imageURL string) ([]ImageData, error)
+}
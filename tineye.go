@@ -0,0 +1,130 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// TineyeProvider searches TinEye by driving its reverse image search
+// upload flow.
+type TineyeProvider struct{}
+
+func (TineyeProvider) Name() string { return "tineye" }
+
+func (t TineyeProvider) SearchByFile(ctx context.Context, path string) ([]ImageData, error) {
+	var file, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileContents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf = new(bytes.Buffer)
+	var writer = multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("image", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(fileContents); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://tineye.com/search", buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.parseResults(contents)
+}
+
+func (t TineyeProvider) SearchByURL(ctx context.Context, imageURL string) ([]ImageData, error) {
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, "https://tineye.com/search?url="+url.QueryEscape(imageURL), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.parseResults(contents)
+}
+
+// parseResults scrapes the data-image-url/data-width/data-height
+// attributes TinEye renders on each match row.
+func (t TineyeProvider) parseResults(contents []byte) ([]ImageData, error) {
+	if bytes.Contains(contents, []byte("captcha")) {
+		return nil, ErrCaptcha
+	}
+
+	var r, err = regexp.Compile(`data-image-url="(.*?)"\s+data-width="(\d+)"\s+data-height="(\d+)"`)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []ImageData
+	for _, i := range r.FindAllStringSubmatch(string(contents), -1) {
+		if len(i) < 4 {
+			continue
+		}
+
+		imgURL, err := url.Parse(i[1])
+		if err != nil {
+			continue
+		}
+
+		width, err := strconv.Atoi(i[2])
+		if err != nil {
+			continue
+		}
+
+		height, err := strconv.Atoi(i[3])
+		if err != nil {
+			continue
+		}
+
+		data = append(data, ImageData{
+			URL:  imgURL.String(),
+			Area: width * height,
+		})
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoResults
+	}
+
+	return data, nil
+}
@@ -0,0 +1,40 @@
+package imageupsizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTineyeProviderParseResults(t *testing.T) {
+	t.Parallel()
+
+	var page = `<div class="match-row">` +
+		`<img data-image-url="https://example.com/cat-large.jpg" data-width="1600" data-height="900">` +
+		`<img data-image-url="https://example.com/cat-small.jpg" data-width="320" data-height="180">` +
+		`</div>`
+
+	var data, err = TineyeProvider{}.parseResults([]byte(page))
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	assert.Equal(t, "https://example.com/cat-large.jpg", data[0].URL)
+	assert.Equal(t, 1600*900, data[0].Area)
+	assert.Equal(t, "https://example.com/cat-small.jpg", data[1].URL)
+	assert.Equal(t, 320*180, data[1].Area)
+}
+
+func TestTineyeProviderParseResultsCaptcha(t *testing.T) {
+	t.Parallel()
+
+	var _, err = TineyeProvider{}.parseResults([]byte(`<div>please solve this captcha</div>`))
+	assert.ErrorIs(t, err, ErrCaptcha)
+}
+
+func TestTineyeProviderParseResultsNoMatches(t *testing.T) {
+	t.Parallel()
+
+	var _, err = TineyeProvider{}.parseResults([]byte(`<html><body>no matches</body></html>`))
+	assert.ErrorIs(t, err, ErrNoResults)
+}
@@ -0,0 +1,115 @@
+package imageupsizer
+
+import (
+	"bytes"
+	"context"
+	"image"
+
+	"github.com/nfnt/resize"
+)
+
+// UpscaleFilter selects the resampling kernel Upscale uses to synthesize
+// new pixels.
+type UpscaleFilter int
+
+const (
+	NearestNeighbor UpscaleFilter = iota
+	Bilinear
+	CatmullRom
+	Lanczos3
+)
+
+func (f UpscaleFilter) interpolation() resize.InterpolationFunction {
+	switch f {
+	case Bilinear:
+		return resize.Bilinear
+	case CatmullRom:
+		return resize.Bicubic
+	case Lanczos3:
+		return resize.Lanczos3
+	default:
+		return resize.NearestNeighbor
+	}
+}
+
+// Upscale resamples data's image up by factor using filter and returns a
+// new ImageData describing the result. It's the fallback used when no
+// larger version of an image can be found on the web.
+func Upscale(data *ImageData, factor float64, filter UpscaleFilter) (*ImageData, error) {
+	img, ext, err := image.Decode(bytes.NewReader(data.Bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var newWidth = uint(float64(data.Config.Width) * factor)
+	var newHeight = uint(float64(data.Config.Height) * factor)
+	var resized = resize.Resize(newWidth, newHeight, img, filter.interpolation())
+
+	if ext != "png" {
+		ext = "jpeg"
+	}
+
+	var buf bytes.Buffer
+	if err := encodeImage(&buf, resized, ext); err != nil {
+		return nil, err
+	}
+
+	return &ImageData{
+		URL:       data.URL,
+		LocalPath: data.LocalPath,
+		Bytes:     buf.Bytes(),
+		Extension: ext,
+		Config: image.Config{
+			ColorModel: resized.ColorModel(),
+			Width:      resized.Bounds().Dx(),
+			Height:     resized.Bounds().Dy(),
+		},
+		Area:        resized.Bounds().Dx() * resized.Bounds().Dy(),
+		FileSize:    int64(buf.Len()),
+		Orientation: data.Orientation,
+		EXIF:        data.EXIF,
+		rawEXIF:     data.rawEXIF,
+	}, nil
+}
+
+// GetBiggerImageOrUpscale behaves like GetBiggerImage, but instead of
+// returning ErrNoLargerAvailable, ErrNoResults, or ErrCaptcha when no
+// bigger version can be found on the web, it falls back to locally
+// upscaling the original by factor using filter.
+func GetBiggerImageOrUpscale(imageURL string, factor float64, filter UpscaleFilter) (*ImageData, error) {
+	bigger, err := GetBiggerImage(imageURL)
+	if err == nil {
+		return bigger, nil
+	}
+	if err != ErrNoLargerAvailable && err != ErrNoResults && err != ErrCaptcha {
+		return nil, err
+	}
+
+	original, err := getImage(context.Background(), imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return Upscale(original, factor, filter)
+}
+
+// GetBiggerImageFromFileOrUpscale behaves like GetBiggerImageFromFile, but
+// instead of returning ErrNoLargerAvailable, ErrNoResults, or ErrCaptcha
+// when no bigger version can be found on the web, it falls back to
+// locally upscaling the original by factor using filter.
+func GetBiggerImageFromFileOrUpscale(filename string, factor float64, filter UpscaleFilter) (*ImageData, error) {
+	bigger, err := GetBiggerImageFromFile(filename)
+	if err == nil {
+		return bigger, nil
+	}
+	if err != ErrNoLargerAvailable && err != ErrNoResults && err != ErrCaptcha {
+		return nil, err
+	}
+
+	original, err := GetImageConfigFromFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Upscale(original, factor, filter)
+}
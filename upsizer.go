@@ -0,0 +1,108 @@
+package imageupsizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// GetBiggerImage takes the URL of an image and returns the largest version
+// of it Google Images can find. For a multi-provider search, build a
+// MetaSearch and call SearchByURL directly instead.
+func GetBiggerImage(imageURL string) (*ImageData, error) {
+	return getBiggerImage(context.Background(), imageURL, nil)
+}
+
+// GetBiggerImageCached behaves like GetBiggerImage, but consults cache
+// (keyed on imageURL) before hitting the network, and populates it with
+// the resolved result afterwards.
+func GetBiggerImageCached(imageURL string, cache Cache) (*ImageData, error) {
+	return getBiggerImage(context.Background(), imageURL, cache)
+}
+
+// GetBiggerImageFromFile takes the path of a local image file and returns
+// the largest version of it Google Images can find. For a multi-provider
+// search, build a MetaSearch and call SearchByFile directly instead.
+func GetBiggerImageFromFile(filename string) (*ImageData, error) {
+	return getBiggerImageFromFile(context.Background(), filename, nil)
+}
+
+// GetBiggerImageFromFileCached behaves like GetBiggerImageFromFile, but
+// consults cache (keyed on the SHA-256 of filename's contents) before
+// hitting the network, and populates it with the resolved result
+// afterwards.
+func GetBiggerImageFromFileCached(filename string, cache Cache) (*ImageData, error) {
+	return getBiggerImageFromFile(context.Background(), filename, cache)
+}
+
+func getBiggerImage(ctx context.Context, imageURL string, cache Cache) (*ImageData, error) {
+	return resolveLargest(ctx, cache, imageURL, func() ([]ImageData, error) {
+		return GoogleProvider{}.SearchByURL(ctx, imageURL)
+	})
+}
+
+func getBiggerImageFromFile(ctx context.Context, filename string, cache Cache) (*ImageData, error) {
+	var key = filename
+	if cache != nil {
+		contents, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var sum = sha256.Sum256(contents)
+		key = hex.EncodeToString(sum[:])
+	}
+
+	return resolveLargest(ctx, cache, key, func() ([]ImageData, error) {
+		return GoogleProvider{}.SearchByFile(ctx, filename)
+	})
+}
+
+// GetBiggerImageTo resolves the largest available version of imageURL and
+// streams it directly into w, without ever buffering it into
+// ImageData.Bytes. Use this instead of GetBiggerImage when the result may
+// be a multi-megabyte upscale and the caller already has somewhere to put
+// the bytes (a file, an HTTP response, etc).
+func GetBiggerImageTo(imageURL string, w io.Writer) (*ImageData, error) {
+	var ctx = context.Background()
+
+	candidates, err := GoogleProvider{}.SearchByURL(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Area > candidates[j].Area
+	})
+
+	return getImageStream(ctx, candidates[0].URL, w)
+}
+
+// resolveLargest returns the cached result for key if present, otherwise
+// runs search, caches its largest candidate's metadata under key, and
+// downloads it (also through the cache, keyed on its own URL).
+func resolveLargest(ctx context.Context, cache Cache, key string, search func() ([]ImageData, error)) (*ImageData, error) {
+	if cache != nil {
+		if resolved, ok := cache.GetResolved(key); ok {
+			return getImage(ctx, resolved.URL, cache)
+		}
+	}
+
+	candidates, err := search()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Area > candidates[j].Area
+	})
+	var winner = candidates[0]
+
+	if cache != nil {
+		_ = cache.PutResolved(key, &winner)
+	}
+
+	return getImage(ctx, winner.URL, cache)
+}
@@ -0,0 +1,38 @@
+package imageupsizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYandexProviderParseResults(t *testing.T) {
+	t.Parallel()
+
+	var page = `{"sites":[{"url":"https://example.com/cat-large.jpg","w":1600,"h":900},` +
+		`{"url":"https://example.com/cat-small.jpg","w":320,"h":180}]}`
+
+	var data, err = YandexProvider{}.parseResults([]byte(page))
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	assert.Equal(t, "https://example.com/cat-large.jpg", data[0].URL)
+	assert.Equal(t, 1600*900, data[0].Area)
+	assert.Equal(t, "https://example.com/cat-small.jpg", data[1].URL)
+	assert.Equal(t, 320*180, data[1].Area)
+}
+
+func TestYandexProviderParseResultsCaptcha(t *testing.T) {
+	t.Parallel()
+
+	var _, err = YandexProvider{}.parseResults([]byte(`<div>please solve this captcha</div>`))
+	assert.ErrorIs(t, err, ErrCaptcha)
+}
+
+func TestYandexProviderParseResultsNoMatches(t *testing.T) {
+	t.Parallel()
+
+	var _, err = YandexProvider{}.parseResults([]byte(`{"sites":[]}`))
+	assert.ErrorIs(t, err, ErrNoResults)
+}